@@ -20,29 +20,178 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/mod/semver"
 )
 
-func genBoilerplate(version, shasum, goVersion string) string {
-	return fmt.Sprintf(`load("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+// minGoVersion is the oldest Go version rules_go supports. It should match
+// MIN_SUPPORTED_VERSION in go/private/sdk.bzl.
+const minGoVersion = "1.14.0"
 
-http_archive(
-    name = "io_bazel_rules_go",
-    sha256 = "%[2]s",
-    urls = [
-        "https://mirror.bazel.build/github.com/bazel-contrib/rules_go/releases/download/%[1]s/rules_go-%[1]s.zip",
-        "https://github.com/bazel-contrib/rules_go/releases/download/%[1]s/rules_go-%[1]s.zip",
-    ],
+const (
+	// defaultRepoName is the name rules_go is declared with in WORKSPACE
+	// boilerplate when no other name is given.
+	defaultRepoName = "io_bazel_rules_go"
+
+	// defaultModuleName is the name rules_go is depended on with in
+	// MODULE.bazel boilerplate when no other name is given.
+	defaultModuleName = "rules_go"
+
+	// defaultGitHubRepo is the GitHub repository, in "owner/name" form, that
+	// releases are published in when no other repository is given.
+	defaultGitHubRepo = "bazel-contrib/rules_go"
 )
 
-load("@io_bazel_rules_go//go:deps.bzl", "go_register_toolchains", "go_rules_dependencies")
+// sdkDownload describes a Go SDK archive for a single host platform.
+type sdkDownload struct {
+	// platform is the host platform in GOOS_GOARCH form, like "linux_amd64".
+	platform string
+
+	// url is the full URL of the SDK archive.
+	url string
+
+	// shasum is the hex-encoded SHA-256 sum of the archive.
+	shasum string
+}
+
+// boilerplateOptions holds optional inputs to genBoilerplate. The zero value
+// generates the default boilerplate.
+type boilerplateOptions struct {
+	// repoName is the name rules_go is declared with in WORKSPACE. If empty,
+	// defaultRepoName is used.
+	repoName string
+
+	// moduleName is the name rules_go is depended on with in MODULE.bazel.
+	// If empty, defaultModuleName is used.
+	moduleName string
+
+	// githubRepo is the GitHub repository the release is published in, in
+	// "owner/name" form. Forks should set this so the boilerplate downloads
+	// their own release archive. If empty, defaultGitHubRepo is used.
+	githubRepo string
+
+	// sdks lists Go SDK archives to declare with go_download_sdk. See
+	// genBoilerplate for how they are used.
+	sdks []sdkDownload
+
+	// stamp is the time the boilerplate was generated. It should come from
+	// sourceDateEpoch. If zero, the boilerplate is not stamped.
+	stamp time.Time
+}
+
+// withDefaults returns a copy of opts with empty names replaced by their
+// default values.
+func (opts boilerplateOptions) withDefaults() boilerplateOptions {
+	if opts.repoName == "" {
+		opts.repoName = defaultRepoName
+	}
+	if opts.moduleName == "" {
+		opts.moduleName = defaultModuleName
+	}
+	if opts.githubRepo == "" {
+		opts.githubRepo = defaultGitHubRepo
+	}
+	return opts
+}
+
+// releaseArchiveURLWithoutScheme returns the URL of the archive attached to
+// the given release in githubRepo, without the "https://" prefix. The same
+// path is used on mirror.bazel.build.
+func releaseArchiveURLWithoutScheme(githubRepo, version string) string {
+	return fmt.Sprintf("github.com/%[1]s/releases/download/%[2]s/rules_go-%[2]s.zip", githubRepo, version)
+}
+
+// validateGitHubRepo returns an error if githubRepo is not in "owner/name"
+// form.
+func validateGitHubRepo(githubRepo string) error {
+	owner, name, ok := strings.Cut(githubRepo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("GitHub repository %q must have the form owner/name", githubRepo)
+	}
+	return nil
+}
+
+// writeReleaseURLs writes a Starlark urls attribute listing the URLs of the
+// release archive. Only the upstream repository's releases are uploaded to
+// mirror.bazel.build, so the mirror URL is listed only when githubRepo is
+// defaultGitHubRepo. Forks get just the GitHub URL.
+func writeReleaseURLs(b *strings.Builder, githubRepo, version string) {
+	arcURL := releaseArchiveURLWithoutScheme(githubRepo, version)
+	b.WriteString("    urls = [\n")
+	if githubRepo == defaultGitHubRepo {
+		fmt.Fprintf(b, "        \"https://mirror.bazel.build/%s\",\n", arcURL)
+	}
+	fmt.Fprintf(b, "        \"https://%s\",\n", arcURL)
+	b.WriteString("    ],\n")
+}
+
+// genBoilerplate returns WORKSPACE code that loads the rules_go release with
+// the given version and shasum and registers a Go toolchain. If opts.stamp
+// is not zero, a comment noting when the boilerplate was generated is added
+// at the top.
+//
+// If more than one SDK download is given in opts.sdks, the toolchain is
+// declared with go_download_sdk, listing each platform's archive file name
+// and shasum. go_download_sdk fetches every archive from the same set of URL
+// templates, so all SDK downloads must be in the same directory;
+// validateSDKDownloads checks this. If only one SDK download is given, its
+// url and shasum are not used, and the toolchain is registered by version
+// alone, as it is when no SDK downloads are given.
+func genBoilerplate(version, shasum, goVersion string, opts boilerplateOptions) string {
+	opts = opts.withDefaults()
+	repoName := opts.repoName
+	b := &strings.Builder{}
+	if !opts.stamp.IsZero() {
+		fmt.Fprintf(b, "# Generated for rules_go %s at %s.\n\n", version, opts.stamp.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(b, `load("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+
+http_archive(
+    name = "%s",
+    sha256 = "%s",
+`, repoName, shasum)
+	writeReleaseURLs(b, opts.githubRepo, version)
+	b.WriteString(")\n\n")
+
+	if len(opts.sdks) <= 1 {
+		fmt.Fprintf(b, `load("@%[1]s//go:deps.bzl", "go_register_toolchains", "go_rules_dependencies")
 
 go_rules_dependencies()
 
-go_register_toolchains(version = "%[3]s")
+go_register_toolchains(version = "%[2]s")
+`, repoName, goVersion)
+	} else {
+		// go_download_sdk formats the URL template with the archive's file
+		// name. All archives share a directory, so the first one's will do.
+		dir, _ := splitSDKURL(opts.sdks[0].url)
 
+		fmt.Fprintf(b, `load("@%s//go:deps.bzl", "go_download_sdk", "go_register_toolchains", "go_rules_dependencies")
+
+go_rules_dependencies()
+
+go_download_sdk(
+    name = "go_sdk",
+    sdks = {
+`, repoName)
+		for _, sdk := range opts.sdks {
+			_, file := splitSDKURL(sdk.url)
+			fmt.Fprintf(b, "        %q: (%q, %q),\n", sdk.platform, file, sdk.shasum)
+		}
+		fmt.Fprintf(b, `    },
+    urls = ["%s{}"],
+    version = "%s",
+)
+
+go_register_toolchains()
+`, dir, goVersion)
+	}
+
+	b.WriteString(`
 # Create the host platform repository transitively required by rules_go.
 load("@bazel_tools//tools/build_defs/repo:utils.bzl", "maybe")
 load("@platforms//host:extension.bzl", "host_platform_repo")
@@ -51,7 +200,103 @@ maybe(
 	host_platform_repo,
 	name = "host_platform",
 )
-`, version, shasum, goVersion)
+`)
+	return b.String()
+}
+
+// genReleaseBoilerplate returns the WORKSPACE and MODULE.bazel code sections
+// that 'prepare' appends to release notes, for the release archive with the
+// given SHA-256 digest.
+func genReleaseBoilerplate(version string, arcDigest []byte, goVersion string, opts boilerplateOptions) string {
+	boilerplate := genBoilerplate(version, sha256Hex(arcDigest), goVersion, opts)
+	moduleBoilerplate := genModuleBoilerplate(version, integrityString(arcDigest), opts)
+	return "## `WORKSPACE` code\n\n```\n" + boilerplate + "\n```\n" +
+		"\n## `MODULE.bazel` code\n\n```\n" + moduleBoilerplate + "\n```\n"
+}
+
+// validateSDKDownloads returns an error if sdks cannot be listed together in
+// a go_download_sdk declaration: each platform must appear at most once, and
+// all archives must be in the same directory.
+func validateSDKDownloads(sdks []sdkDownload) error {
+	seenPlatforms := make(map[string]bool)
+	var firstDir string
+	for i, sdk := range sdks {
+		if seenPlatforms[sdk.platform] {
+			return fmt.Errorf("multiple SDK downloads for platform %s", sdk.platform)
+		}
+		seenPlatforms[sdk.platform] = true
+		dir, file := splitSDKURL(sdk.url)
+		if file == "" {
+			return fmt.Errorf("SDK download URL for %s has no file name: %s", sdk.platform, sdk.url)
+		}
+		if i == 0 {
+			firstDir = dir
+		} else if dir != firstDir {
+			return fmt.Errorf("SDK downloads for %s and %s are in different directories (%s and %s); go_download_sdk requires a common directory", sdks[0].platform, sdk.platform, firstDir, dir)
+		}
+	}
+	return nil
+}
+
+// splitSDKURL splits an SDK download URL into its directory, including the
+// trailing slash, and its file name.
+func splitSDKURL(url string) (dir, file string) {
+	i := strings.LastIndex(url, "/") + 1
+	return url[:i], url[i:]
+}
+
+// genModuleBoilerplate returns MODULE.bazel code that depends on the rules_go
+// release with the given version, fetched from the release archive with the
+// given Subresource Integrity string. Only the names in opts are used.
+func genModuleBoilerplate(version, integrity string, opts boilerplateOptions) string {
+	opts = opts.withDefaults()
+	b := &strings.Builder{}
+	fmt.Fprintf(b, `bazel_dep(name = "%[1]s", version = "%[2]s")
+
+archive_override(
+    module_name = "%[1]s",
+    integrity = "%[3]s",
+`, opts.moduleName, strings.TrimPrefix(version, "v"), integrity)
+	writeReleaseURLs(b, opts.githubRepo, version)
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// sourceDateEpoch returns the time given by the SOURCE_DATE_EPOCH environment
+// variable, in seconds since the Unix epoch. Timestamps embedded in release
+// boilerplate must come from here rather than the current time so releases
+// are reproducible. If SOURCE_DATE_EPOCH is not set, sourceDateEpoch returns
+// the zero time.
+//
+// See https://reproducible-builds.org/specs/source-date-epoch/.
+func sourceDateEpoch() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Time{}, nil
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: must be a number of seconds", epoch)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+// validateGoVersion returns an error if actual is not a valid Go version or
+// if it is older than min. Both versions are given without the "go" prefix,
+// for example, "1.21.0".
+func validateGoVersion(min, actual string) error {
+	vmin := "v" + min
+	if !semver.IsValid(vmin) {
+		return fmt.Errorf("invalid minimum Go version %q", min)
+	}
+	vactual := "v" + actual
+	if !semver.IsValid(vactual) {
+		return fmt.Errorf("invalid Go version %q", actual)
+	}
+	if semver.Compare(vactual, vmin) < 0 {
+		return fmt.Errorf("Go version %s is older than the minimum supported version %s", actual, min)
+	}
+	return nil
 }
 
 func findLatestGoVersion() (v string, err error) {
@@ -86,3 +331,57 @@ func findLatestGoVersion() (v string, err error) {
 	})
 	return versions[0].Version[len("go"):], nil
 }
+
+// findGoSDKDownloads returns the binary archives published for each host
+// platform for the given Go version, which is given without the "go" prefix.
+// The archives are checked with validateSDKDownloads.
+func findGoSDKDownloads(goVersion string) (sdks []sdkDownload, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("finding go %s downloads: %w", goVersion, err)
+		}
+	}()
+	resp, err := http.Get("https://golang.org/dl/?mode=json&include=all")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	type file struct {
+		Filename, OS, Arch, Sha256, Kind string
+	}
+	type version struct {
+		Version string
+		Files   []file
+	}
+	var versions []version
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.Version != "go"+goVersion {
+			continue
+		}
+		for _, f := range v.Files {
+			if f.Kind != "archive" || f.OS == "" || f.Arch == "" {
+				continue
+			}
+			sdks = append(sdks, sdkDownload{
+				platform: f.OS + "_" + f.Arch,
+				url:      "https://dl.google.com/go/" + f.Filename,
+				shasum:   f.Sha256,
+			})
+		}
+		sort.Slice(sdks, func(i, j int) bool {
+			return sdks[i].platform < sdks[j].platform
+		})
+		if err := validateSDKDownloads(sdks); err != nil {
+			return nil, err
+		}
+		return sdks, nil
+	}
+	return nil, errors.New("version not found")
+}
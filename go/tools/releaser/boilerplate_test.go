@@ -0,0 +1,327 @@
+// Copyright 2021 The Bazel Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateGoVersion(t *testing.T) {
+	for _, tt := range []struct {
+		desc, min, actual string
+		wantErr           bool
+	}{
+		{
+			desc:   "equal",
+			min:    "1.14.0",
+			actual: "1.14.0",
+		},
+		{
+			desc:   "equal_short",
+			min:    "1.14.0",
+			actual: "1.14",
+		},
+		{
+			desc:   "above",
+			min:    "1.14.0",
+			actual: "1.22.3",
+		},
+		{
+			desc:    "below",
+			min:     "1.14.0",
+			actual:  "1.13.15",
+			wantErr: true,
+		},
+		{
+			desc:    "invalid",
+			min:     "1.14.0",
+			actual:  "tip",
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := validateGoVersion(tt.min, tt.actual)
+			if tt.wantErr && err == nil {
+				t.Errorf("validateGoVersion(%q, %q): got nil error, want error", tt.min, tt.actual)
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("validateGoVersion(%q, %q): unexpected error: %v", tt.min, tt.actual, err)
+			}
+		})
+	}
+}
+
+func TestGenBoilerplate(t *testing.T) {
+	for _, tt := range []struct {
+		desc string
+		sdks []sdkDownload
+		want string
+	}{
+		{
+			desc: "single",
+			sdks: []sdkDownload{
+				{platform: "linux_amd64", url: "https://dl.google.com/go/go1.22.3.linux-amd64.tar.gz", shasum: "8920ea521bad8f6b7bc377b4824982e011c19af27df88a815e3586ea895f1b36"},
+			},
+			want: `load("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+
+http_archive(
+    name = "io_bazel_rules_go",
+    sha256 = "0123456789abcdef",
+    urls = [
+        "https://mirror.bazel.build/github.com/bazel-contrib/rules_go/releases/download/v0.50.0/rules_go-v0.50.0.zip",
+        "https://github.com/bazel-contrib/rules_go/releases/download/v0.50.0/rules_go-v0.50.0.zip",
+    ],
+)
+
+load("@io_bazel_rules_go//go:deps.bzl", "go_register_toolchains", "go_rules_dependencies")
+
+go_rules_dependencies()
+
+go_register_toolchains(version = "1.22.3")
+
+# Create the host platform repository transitively required by rules_go.
+load("@bazel_tools//tools/build_defs/repo:utils.bzl", "maybe")
+load("@platforms//host:extension.bzl", "host_platform_repo")
+
+maybe(
+	host_platform_repo,
+	name = "host_platform",
+)
+`,
+		},
+		{
+			desc: "multi_platform",
+			sdks: []sdkDownload{
+				{platform: "darwin_arm64", url: "https://dl.google.com/go/go1.22.3.darwin-arm64.tar.gz", shasum: "02abeab3f4b8981232237ebd88f0a9bad933bc9621791cd7720a9ca29eacbe9d"},
+				{platform: "linux_amd64", url: "https://dl.google.com/go/go1.22.3.linux-amd64.tar.gz", shasum: "8920ea521bad8f6b7bc377b4824982e011c19af27df88a815e3586ea895f1b36"},
+				{platform: "windows_amd64", url: "https://dl.google.com/go/go1.22.3.windows-amd64.zip", shasum: "cab2af6951a6e2115824263f6df13ff069c47270f5788714fa1d776f7f60cb39"},
+			},
+			want: `load("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+
+http_archive(
+    name = "io_bazel_rules_go",
+    sha256 = "0123456789abcdef",
+    urls = [
+        "https://mirror.bazel.build/github.com/bazel-contrib/rules_go/releases/download/v0.50.0/rules_go-v0.50.0.zip",
+        "https://github.com/bazel-contrib/rules_go/releases/download/v0.50.0/rules_go-v0.50.0.zip",
+    ],
+)
+
+load("@io_bazel_rules_go//go:deps.bzl", "go_download_sdk", "go_register_toolchains", "go_rules_dependencies")
+
+go_rules_dependencies()
+
+go_download_sdk(
+    name = "go_sdk",
+    sdks = {
+        "darwin_arm64": ("go1.22.3.darwin-arm64.tar.gz", "02abeab3f4b8981232237ebd88f0a9bad933bc9621791cd7720a9ca29eacbe9d"),
+        "linux_amd64": ("go1.22.3.linux-amd64.tar.gz", "8920ea521bad8f6b7bc377b4824982e011c19af27df88a815e3586ea895f1b36"),
+        "windows_amd64": ("go1.22.3.windows-amd64.zip", "cab2af6951a6e2115824263f6df13ff069c47270f5788714fa1d776f7f60cb39"),
+    },
+    urls = ["https://dl.google.com/go/{}"],
+    version = "1.22.3",
+)
+
+go_register_toolchains()
+
+# Create the host platform repository transitively required by rules_go.
+load("@bazel_tools//tools/build_defs/repo:utils.bzl", "maybe")
+load("@platforms//host:extension.bzl", "host_platform_repo")
+
+maybe(
+	host_platform_repo,
+	name = "host_platform",
+)
+`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := genBoilerplate("v0.50.0", "0123456789abcdef", "1.22.3", boilerplateOptions{sdks: tt.sdks})
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSDKDownloads(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		sdks    []sdkDownload
+		wantErr bool
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc: "same_directory",
+			sdks: []sdkDownload{
+				{platform: "darwin_arm64", url: "https://dl.google.com/go/go1.22.3.darwin-arm64.tar.gz"},
+				{platform: "linux_amd64", url: "https://dl.google.com/go/go1.22.3.linux-amd64.tar.gz"},
+			},
+		},
+		{
+			desc: "different_directories",
+			sdks: []sdkDownload{
+				{platform: "linux_amd64", url: "https://dl.google.com/go/go1.22.3.linux-amd64.tar.gz"},
+				{platform: "windows_amd64", url: "https://mirror.example.com/go/go1.22.3.windows-amd64.zip"},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "duplicate_platform",
+			sdks: []sdkDownload{
+				{platform: "linux_amd64", url: "https://dl.google.com/go/go1.22.3.linux-amd64.tar.gz"},
+				{platform: "linux_amd64", url: "https://dl.google.com/go/go1.22.2.linux-amd64.tar.gz"},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "no_file_name",
+			sdks: []sdkDownload{
+				{platform: "linux_amd64", url: "https://dl.google.com/go/"},
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := validateSDKDownloads(tt.sdks)
+			if tt.wantErr && err == nil {
+				t.Error("got nil error, want error")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGenModuleBoilerplate(t *testing.T) {
+	for _, tt := range []struct {
+		desc string
+		opts boilerplateOptions
+		want string
+	}{
+		{
+			desc: "default",
+			want: `bazel_dep(name = "rules_go", version = "0.50.0")
+
+archive_override(
+    module_name = "rules_go",
+    integrity = "sha256-xWiVmcxJmXdtXzrF1VA/W1FNWZgoVXHvzSY2Zv5B3Nw=",
+    urls = [
+        "https://mirror.bazel.build/github.com/bazel-contrib/rules_go/releases/download/v0.50.0/rules_go-v0.50.0.zip",
+        "https://github.com/bazel-contrib/rules_go/releases/download/v0.50.0/rules_go-v0.50.0.zip",
+    ],
+)
+`,
+		},
+		{
+			desc: "fork",
+			opts: boilerplateOptions{
+				moduleName: "darccio_rules_go",
+				githubRepo: "darccio/rules_go",
+			},
+			want: `bazel_dep(name = "darccio_rules_go", version = "0.50.0")
+
+archive_override(
+    module_name = "darccio_rules_go",
+    integrity = "sha256-xWiVmcxJmXdtXzrF1VA/W1FNWZgoVXHvzSY2Zv5B3Nw=",
+    urls = [
+        "https://github.com/darccio/rules_go/releases/download/v0.50.0/rules_go-v0.50.0.zip",
+    ],
+)
+`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := genModuleBoilerplate("v0.50.0", "sha256-xWiVmcxJmXdtXzrF1VA/W1FNWZgoVXHvzSY2Zv5B3Nw=", tt.opts)
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenBoilerplateFork(t *testing.T) {
+	got := genBoilerplate("v0.50.0", "fedcba9876543210", "1.22.3", boilerplateOptions{
+		repoName:   "com_github_darccio_rules_go",
+		githubRepo: "darccio/rules_go",
+	})
+	want := `load("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+
+http_archive(
+    name = "com_github_darccio_rules_go",
+    sha256 = "fedcba9876543210",
+    urls = [
+        "https://github.com/darccio/rules_go/releases/download/v0.50.0/rules_go-v0.50.0.zip",
+    ],
+)
+
+load("@com_github_darccio_rules_go//go:deps.bzl", "go_register_toolchains", "go_rules_dependencies")
+
+go_rules_dependencies()
+
+go_register_toolchains(version = "1.22.3")
+
+# Create the host platform repository transitively required by rules_go.
+load("@bazel_tools//tools/build_defs/repo:utils.bzl", "maybe")
+load("@platforms//host:extension.bzl", "host_platform_repo")
+
+maybe(
+	host_platform_repo,
+	name = "host_platform",
+)
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestValidateGitHubRepo(t *testing.T) {
+	for _, repo := range []string{"bazel-contrib/rules_go", "darccio/rules_go"} {
+		if err := validateGitHubRepo(repo); err != nil {
+			t.Errorf("validateGitHubRepo(%q): unexpected error: %v", repo, err)
+		}
+	}
+	for _, repo := range []string{"", "rules_go", "/rules_go", "darccio/", "darccio/rules_go/extra"} {
+		if err := validateGitHubRepo(repo); err == nil {
+			t.Errorf("validateGitHubRepo(%q): got nil error, want error", repo)
+		}
+	}
+}
+
+func TestGenBoilerplateStamp(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1714564800")
+	gen := func() string {
+		stamp, err := sourceDateEpoch()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return genBoilerplate("v0.50.0", "0123456789abcdef", "1.22.3", boilerplateOptions{stamp: stamp})
+	}
+
+	first, second := gen(), gen()
+	if first != second {
+		t.Errorf("output differs between runs with the same epoch:\n%s\n%s", first, second)
+	}
+	if want := "# Generated for rules_go v0.50.0 at 2024-05-01T12:00:00Z.\n\n"; !strings.HasPrefix(first, want) {
+		t.Errorf("got:\n%s\nwant prefix:\n%s", first, want)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if _, err := sourceDateEpoch(); err == nil {
+		t.Error("sourceDateEpoch: got nil error for invalid SOURCE_DATE_EPOCH")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"compress/gzip"
 	"context"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -276,18 +277,31 @@ func copyFile(toFile, fromFile string) (err error) {
 	return err
 }
 
-func sha256SumFile(name string) (string, error) {
+// sha256Hex returns the hex encoding of a SHA-256 sum, as used in the sha256
+// attribute of http_archive.
+func sha256Hex(sum []byte) string {
+	return hex.EncodeToString(sum)
+}
+
+// integrityString returns the Subresource Integrity encoding of a SHA-256
+// sum, as used in the integrity attribute of archive_override and other
+// Bazel module repository rules.
+func integrityString(sum []byte) string {
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum)
+}
+
+// sha256File returns the SHA-256 sum of a file.
+func sha256File(name string) ([]byte, error) {
 	r, err := os.Open(name)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer r.Close()
 	h := sha256.New()
 	if _, err := io.Copy(h, r); err != nil {
-		return "", err
+		return nil, err
 	}
-	sum := h.Sum(nil)
-	return hex.EncodeToString(sum), nil
+	return h.Sum(nil), nil
 }
 
 // copyFileToMirror uploads a file to the GCS bucket backing mirror.bazel.build.
@@ -0,0 +1,39 @@
+// Copyright 2021 The Bazel Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveSums(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "rules_go.zip")
+	if err := os.WriteFile(name, []byte("rules_go\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := sha256File(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sha256Hex(sum), "c5689599cc4999776d5f3ac5d5503f5b514d5998285571efcd263666fe41dcdc"; got != want {
+		t.Errorf("sha256Hex: got %q, want %q", got, want)
+	}
+	if got, want := integrityString(sum), "sha256-xWiVmcxJmXdtXzrF1VA/W1FNWZgoVXHvzSY2Zv5B3Nw="; got != want {
+		t.Errorf("integrityString: got %q, want %q", got, want)
+	}
+}
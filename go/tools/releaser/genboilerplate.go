@@ -0,0 +1,151 @@
+// Copyright 2021 The Bazel Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"golang.org/x/mod/semver"
+)
+
+var genBoilerplateCmd = command{
+	name:        "gen-boilerplate",
+	description: "prints or checks the boilerplate for a release",
+	help: `gen-boilerplate -version=version -archive=file [-goversion=version] [-pinsdks] [-reponame=name] [-modulename=name] [-githubrepo=owner/name] [-check=file]
+
+'gen-boilerplate' generates the WORKSPACE and MODULE.bazel code sections that
+'prepare' appends to release notes, using the sums of the given release
+archive. -pinsdks, -reponame, -modulename and -githubrepo have the same
+meaning as for 'prepare', and the output is the same as what 'prepare'
+publishes when given the same flags. If -goversion is not set, the latest Go
+version is used, as in 'prepare'. With -pinsdks, the per-platform Go SDK
+archives are looked up on golang.org.
+
+If the SOURCE_DATE_EPOCH environment variable is set, the boilerplate is
+stamped with that time.
+
+By default, the boilerplate is printed to stdout. If -check is set, the
+boilerplate is compared against the contents of the given file instead, and
+'gen-boilerplate' exits with a non-zero status and prints a unified diff if
+they differ. This may be used to check that a committed copy of the
+boilerplate is up to date before a release. -goversion must be set with
+-check, so the result does not change when a new Go version is released.
+`,
+}
+
+func init() {
+	// break init cycle
+	genBoilerplateCmd.run = runGenBoilerplate
+}
+
+func runGenBoilerplate(ctx context.Context, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("releaser gen-boilerplate", flag.ContinueOnError)
+	var version, archivePath, goVersion, repoName, moduleName, githubRepo, checkPath string
+	var pinSDKs bool
+	flags.StringVar(&version, "version", "", "Version of the release")
+	flags.StringVar(&archivePath, "archive", "", "Name of file containing the release archive")
+	flags.StringVar(&goVersion, "goversion", "", "Go version to register, without the \"go\" prefix")
+	flags.BoolVar(&pinSDKs, "pinsdks", false, "whether to list per-platform Go SDK archives in the boilerplate")
+	flags.StringVar(&repoName, "reponame", defaultRepoName, "Repository name used for rules_go in the WORKSPACE boilerplate")
+	flags.StringVar(&moduleName, "modulename", defaultModuleName, "Module name used for rules_go in the MODULE.bazel boilerplate")
+	flags.StringVar(&githubRepo, "githubrepo", defaultGitHubRepo, "GitHub repository the release is published in, as owner/name")
+	flags.StringVar(&checkPath, "check", "", "Name of file containing boilerplate to compare against")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageErrorf(&genBoilerplateCmd, "No arguments expected")
+	}
+	if version == "" {
+		return usageErrorf(&genBoilerplateCmd, "-version must be set")
+	}
+	if semver.Canonical(version) != version || semver.Build(version) != "" {
+		return usageErrorf(&genBoilerplateCmd, "-version must be a canonical version, like v1.2.3")
+	}
+	if archivePath == "" {
+		return usageErrorf(&genBoilerplateCmd, "-archive must be set")
+	}
+	if err := validateGitHubRepo(githubRepo); err != nil {
+		return usageErrorf(&genBoilerplateCmd, "-githubrepo: %v", err)
+	}
+	if checkPath != "" && goVersion == "" {
+		return usageErrorf(&genBoilerplateCmd, "-goversion must be set with -check")
+	}
+
+	arcDigest, err := sha256File(archivePath)
+	if err != nil {
+		return err
+	}
+	if goVersion == "" {
+		if goVersion, err = findLatestGoVersion(); err != nil {
+			return err
+		}
+	}
+	if err := validateGoVersion(minGoVersion, goVersion); err != nil {
+		return err
+	}
+	var sdks []sdkDownload
+	if pinSDKs {
+		if sdks, err = findGoSDKDownloads(goVersion); err != nil {
+			return err
+		}
+	}
+	stamp, err := sourceDateEpoch()
+	if err != nil {
+		return err
+	}
+	boilerplate := genReleaseBoilerplate(version, arcDigest, goVersion, boilerplateOptions{
+		repoName:   repoName,
+		moduleName: moduleName,
+		githubRepo: githubRepo,
+		sdks:       sdks,
+		stamp:      stamp,
+	})
+
+	if checkPath == "" {
+		_, err := io.WriteString(os.Stdout, boilerplate)
+		return err
+	}
+	want, err := os.ReadFile(checkPath)
+	if err != nil {
+		return err
+	}
+	return checkBoilerplate(checkPath, want, boilerplate)
+}
+
+// checkBoilerplate returns an error containing a unified diff if the
+// generated boilerplate differs from want, which was read from the file name.
+func checkBoilerplate(name string, want []byte, boilerplate string) error {
+	if bytes.Equal(want, []byte(boilerplate)) {
+		return nil
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(want)),
+		B:        difflib.SplitLines(boilerplate),
+		FromFile: name,
+		ToFile:   "generated",
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%s does not match the generated boilerplate:\n%s", name, diff)
+}
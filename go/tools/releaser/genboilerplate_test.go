@@ -0,0 +1,110 @@
+// Copyright 2021 The Bazel Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckBoilerplate(t *testing.T) {
+	digest := []byte("0123456789abcdef0123456789abcdef")
+	boilerplate := genReleaseBoilerplate("v0.50.0", digest, "1.22.3", boilerplateOptions{})
+
+	t.Run("match", func(t *testing.T) {
+		if err := checkBoilerplate("boilerplate.txt", []byte(boilerplate), boilerplate); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		committed := genReleaseBoilerplate("v0.49.0", digest, "1.22.3", boilerplateOptions{})
+		err := checkBoilerplate("boilerplate.txt", []byte(committed), boilerplate)
+		if err == nil {
+			t.Fatal("got nil error, want error")
+		}
+		for _, want := range []string{
+			"--- boilerplate.txt",
+			"+++ generated",
+			"-        \"https://github.com/bazel-contrib/rules_go/releases/download/v0.49.0/rules_go-v0.49.0.zip\",",
+			"+        \"https://github.com/bazel-contrib/rules_go/releases/download/v0.50.0/rules_go-v0.50.0.zip\",",
+			"-bazel_dep(name = \"rules_go\", version = \"0.49.0\")",
+			"+bazel_dep(name = \"rules_go\", version = \"0.50.0\")",
+		} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error does not contain %q:\n%v", want, err)
+			}
+		}
+	})
+}
+
+func TestGenBoilerplateCheckRequiresGoVersion(t *testing.T) {
+	args := []string{"-version=v0.50.0", "-archive=rules_go.zip", "-check=boilerplate.txt"}
+	err := runGenBoilerplate(context.Background(), io.Discard, args)
+	var uerr *usageError
+	if !errors.As(err, &uerr) || !strings.Contains(err.Error(), "-goversion must be set with -check") {
+		t.Errorf("got error %v, want usage error about -goversion", err)
+	}
+}
+
+func TestGenBoilerplateCheck(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "rules_go.zip")
+	if err := os.WriteFile(archivePath, []byte("rules_go\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := sha256File(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPath := filepath.Join(dir, "boilerplate.md")
+	committed := genReleaseBoilerplate("v0.50.0", digest, "1.22.3", boilerplateOptions{
+		moduleName: "darccio_rules_go",
+		githubRepo: "darccio/rules_go",
+	})
+	if err := os.WriteFile(checkPath, []byte(committed), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{
+		"-version=v0.50.0",
+		"-archive=" + archivePath,
+		"-goversion=1.22.3",
+		"-githubrepo=darccio/rules_go",
+		"-check=" + checkPath,
+	}
+
+	t.Run("match", func(t *testing.T) {
+		if err := runGenBoilerplate(context.Background(), io.Discard, append(args, "-modulename=darccio_rules_go")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("module_drift", func(t *testing.T) {
+		err := runGenBoilerplate(context.Background(), io.Discard, args)
+		if err == nil {
+			t.Fatal("got nil error, want error")
+		}
+		if want := "+bazel_dep(name = \"rules_go\", version = \"0.50.0\")"; !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not contain %q:\n%v", want, err)
+		}
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/google/go-github/v36/github"
 	"golang.org/x/mod/semver"
@@ -31,7 +32,7 @@ import (
 var prepareCmd = command{
 	name:        "prepare",
 	description: "prepares a GitHub release with notes and attached archive",
-	help: `prepare -rnotes=file -version=version -githubtoken=token [-mirror]
+	help: `prepare -rnotes=file -version=version -githubtoken=token [-mirror] [-pinsdks] [-reponame=name] [-modulename=name] [-githubrepo=owner/name]
 
 'prepare' performs most tasks related to a rules_go release. It does everything
 except publishing and tagging the release, which must be done manually,
@@ -42,13 +43,24 @@ with review. Specifically, prepare does the following:
   numbers.
 * Creates an archive zip file from the tip of the local release branch.
 * Creates or updates a draft GitHub release with the given release notes.
-  http_archive boilerplate is generated and appended to the release notes.
+  http_archive and archive_override boilerplate is generated and appended to
+  the release notes.
+  With -pinsdks, the boilerplate lists the Go SDK archive and shasum for
+  each host platform.
+  If the SOURCE_DATE_EPOCH environment variable is set, the boilerplate is
+  stamped with that time.
 * Uploads and attaches the release archive to the GitHub release.
 * Uploads the release archive to mirror.bazel.build. If the file already exists,
   it may be manually removed with 'gsutil rm gs://bazel-mirror/<github-url>'
   or manually updated with 'gsutil cp <file> gs://bazel-mirror/<github-url>'.
   This step may be skipped by setting -mirror=false.
 
+Forks may set -githubrepo to publish the release in their own GitHub
+repository, and -reponame and -modulename to change the names rules_go is
+declared with in the WORKSPACE and MODULE.bazel boilerplate. Only upstream
+releases are uploaded to mirror.bazel.build, so boilerplate for a fork lists
+only the GitHub URL.
+
 After these steps are completed successfully, 'prepare' prompts the user to
 check that CI passes, then review and publish the release.
 
@@ -65,13 +77,17 @@ func init() {
 func runPrepare(ctx context.Context, stderr io.Writer, args []string) error {
 	// Parse arguments.
 	flags := flag.NewFlagSet("releaser prepare", flag.ContinueOnError)
-	var rnotesPath, version string
+	var rnotesPath, version, repoName, moduleName, githubRepo string
 	var githubToken githubTokenFlag
-	var uploadToMirror bool
+	var uploadToMirror, pinSDKs bool
 	flags.Var(&githubToken, "githubtoken", "GitHub personal access token or path to a file containing it")
 	flags.BoolVar(&uploadToMirror, "mirror", false, "whether to upload dependency archives to mirror.bazel.build")
+	flags.BoolVar(&pinSDKs, "pinsdks", false, "whether to list per-platform Go SDK archives in the boilerplate")
 	flags.StringVar(&rnotesPath, "rnotes", "", "Name of file containing release notes in Markdown")
 	flags.StringVar(&version, "version", "", "Version to release")
+	flags.StringVar(&repoName, "reponame", defaultRepoName, "Repository name used for rules_go in the WORKSPACE boilerplate")
+	flags.StringVar(&moduleName, "modulename", defaultModuleName, "Module name used for rules_go in the MODULE.bazel boilerplate")
+	flags.StringVar(&githubRepo, "githubrepo", defaultGitHubRepo, "GitHub repository to publish the release in, as owner/name")
 	if err := flags.Parse(args); err != nil {
 		return err
 	}
@@ -90,6 +106,10 @@ func runPrepare(ctx context.Context, stderr io.Writer, args []string) error {
 	if semver.Canonical(version) != version || semver.Build(version) != "" {
 		return usageErrorf(&prepareCmd, "-version must be a canonical version, like v1.2.3")
 	}
+	if err := validateGitHubRepo(githubRepo); err != nil {
+		return usageErrorf(&prepareCmd, "-githubrepo: %v", err)
+	}
+	ghOwner, ghName, _ := strings.Cut(githubRepo, "/")
 
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: string(githubToken)})
 	tc := oauth2.NewClient(ctx, ts)
@@ -97,7 +117,7 @@ func runPrepare(ctx context.Context, stderr io.Writer, args []string) error {
 
 	// Get the GitHub release.
 	fmt.Fprintf(stderr, "checking if release %s exists...\n", version)
-	release, err := gh.getReleaseByTagIncludingDraft(ctx, "bazel-contrib", "rules_go", version)
+	release, err := gh.getReleaseByTagIncludingDraft(ctx, ghOwner, ghName, version)
 	if err != nil && !errors.Is(err, errReleaseNotFound) {
 		return err
 	}
@@ -144,7 +164,7 @@ func runPrepare(ctx context.Context, stderr io.Writer, args []string) error {
 	if err := gitCreateArchive(ctx, rootDir, branchName, arcName); err != nil {
 		return err
 	}
-	arcSum, err := sha256SumFile(arcName)
+	arcDigest, err := sha256File(arcName)
 	if err != nil {
 		return err
 	}
@@ -159,8 +179,27 @@ func runPrepare(ctx context.Context, stderr io.Writer, args []string) error {
 	if err != nil {
 		return err
 	}
-	boilerplate := genBoilerplate(version, arcSum, goVersion)
-	rnotesStr := string(rnotesData) + "\n\n## `WORKSPACE` code\n\n```\n" + boilerplate + "\n```\n"
+	if err := validateGoVersion(minGoVersion, goVersion); err != nil {
+		return err
+	}
+	var sdks []sdkDownload
+	if pinSDKs {
+		if sdks, err = findGoSDKDownloads(goVersion); err != nil {
+			return err
+		}
+	}
+	stamp, err := sourceDateEpoch()
+	if err != nil {
+		return err
+	}
+	boilerplate := genReleaseBoilerplate(version, arcDigest, goVersion, boilerplateOptions{
+		repoName:   repoName,
+		moduleName: moduleName,
+		githubRepo: githubRepo,
+		sdks:       sdks,
+		stamp:      stamp,
+	})
+	rnotesStr := string(rnotesData) + "\n\n" + boilerplate
 
 	// Push the release branch.
 	fmt.Fprintf(stderr, "pushing branch %s to origin...\n", branchName)
@@ -169,7 +208,7 @@ func runPrepare(ctx context.Context, stderr io.Writer, args []string) error {
 	}
 
 	// Upload to mirror.bazel.build.
-	arcGHURLWithoutScheme := fmt.Sprintf("github.com/bazel-contrib/rules_go/releases/download/%[1]s/rules_go-%[1]s.zip", version)
+	arcGHURLWithoutScheme := releaseArchiveURLWithoutScheme(githubRepo, version)
 	if uploadToMirror {
 		fmt.Fprintf(stderr, "uploading archive to mirror.bazel.build...\n")
 		if err := copyFileToMirror(ctx, arcGHURLWithoutScheme, arcName); err != nil {
@@ -188,17 +227,17 @@ func runPrepare(ctx context.Context, stderr io.Writer, args []string) error {
 			Body:            &rnotesStr,
 			Draft:           &draft,
 		}
-		if release, _, err = gh.Repositories.CreateRelease(ctx, "bazel-contrib", "rules_go", release); err != nil {
+		if release, _, err = gh.Repositories.CreateRelease(ctx, ghOwner, ghName, release); err != nil {
 			return err
 		}
 	} else {
 		fmt.Fprintf(stderr, "updating release...\n")
 		release.Body = &rnotesStr
-		if release, _, err = gh.Repositories.EditRelease(ctx, "bazel-contrib", "rules_go", release.GetID(), release); err != nil {
+		if release, _, err = gh.Repositories.EditRelease(ctx, ghOwner, ghName, release.GetID(), release); err != nil {
 			return err
 		}
 		for _, asset := range release.Assets {
-			if _, err := gh.Repositories.DeleteReleaseAsset(ctx, "bazel-contrib", "rules_go", asset.GetID()); err != nil {
+			if _, err := gh.Repositories.DeleteReleaseAsset(ctx, ghOwner, ghName, asset.GetID()); err != nil {
 				return err
 			}
 		}
@@ -212,7 +251,7 @@ func runPrepare(ctx context.Context, stderr io.Writer, args []string) error {
 		Name:      "rules_go-" + version + ".zip",
 		MediaType: "application/zip",
 	}
-	if _, _, err := gh.Repositories.UploadReleaseAsset(ctx, "bazel-contrib", "rules_go", release.GetID(), uploadOpts, arcFile); err != nil {
+	if _, _, err := gh.Repositories.UploadReleaseAsset(ctx, ghOwner, ghName, release.GetID(), uploadOpts, arcFile); err != nil {
 		return err
 	}
 
@@ -42,8 +42,10 @@ type command struct {
 
 var commands = []*command{
 	&helpCmd,
+	&genBoilerplateCmd,
 	&prepareCmd,
 	&upgradeDepCmd,
+	&verifyCmd,
 }
 
 func run(ctx context.Context, stderr io.Writer, args []string) error {
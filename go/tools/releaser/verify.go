@@ -0,0 +1,185 @@
+// Copyright 2021 The Bazel Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+
+	"golang.org/x/mod/semver"
+)
+
+var verifyCmd = command{
+	name:        "verify",
+	description: "checks that a release archive matches the sums in its boilerplate",
+	help: `verify -version=version -boilerplate=file [-archive=file] [-githubrepo=owner/name]
+
+'verify' checks that a published release archive matches the sums advertised
+in its boilerplate. The boilerplate file may contain the release notes or just
+the generated WORKSPACE and MODULE.bazel code; verify looks for the sha256 and
+integrity attributes within it.
+
+If -archive is set, verify reads the release archive from that file. Otherwise,
+verify downloads the archive attached to the release for -version in the
+GitHub repository given by -githubrepo. If -version is set, verify also
+checks that the boilerplate downloads the release for -version.
+
+verify exits with a non-zero status if the archive's SHA-256 sum does not match
+the boilerplate.
+`,
+}
+
+func init() {
+	// break init cycle
+	verifyCmd.run = runVerify
+}
+
+func runVerify(ctx context.Context, stderr io.Writer, args []string) (err error) {
+	flags := flag.NewFlagSet("releaser verify", flag.ContinueOnError)
+	var version, boilerplatePath, archivePath, githubRepo string
+	flags.StringVar(&version, "version", "", "Version of the release to verify")
+	flags.StringVar(&boilerplatePath, "boilerplate", "", "Name of file containing the release boilerplate")
+	flags.StringVar(&archivePath, "archive", "", "Name of a local copy of the release archive")
+	flags.StringVar(&githubRepo, "githubrepo", defaultGitHubRepo, "GitHub repository the release is published in, as owner/name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageErrorf(&verifyCmd, "No arguments expected")
+	}
+	if boilerplatePath == "" {
+		return usageErrorf(&verifyCmd, "-boilerplate must be set")
+	}
+	if archivePath == "" && version == "" {
+		return usageErrorf(&verifyCmd, "-version must be set unless -archive is set")
+	}
+	if version != "" && (semver.Canonical(version) != version || semver.Build(version) != "") {
+		return usageErrorf(&verifyCmd, "-version must be a canonical version, like v1.2.3")
+	}
+	if err := validateGitHubRepo(githubRepo); err != nil {
+		return usageErrorf(&verifyCmd, "-githubrepo: %v", err)
+	}
+
+	boilerplate, err := os.ReadFile(boilerplatePath)
+	if err != nil {
+		return err
+	}
+
+	if archivePath == "" {
+		arcURL := "https://" + releaseArchiveURLWithoutScheme(githubRepo, version)
+		fmt.Fprintf(stderr, "downloading %s...\n", arcURL)
+		var cleanup func() error
+		archivePath, cleanup, err = downloadArchive(ctx, arcURL)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := cleanup(); err == nil && cerr != nil {
+				err = cerr
+			}
+		}()
+	}
+
+	if err := verifyArchiveSums(archivePath, version, boilerplate); err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "%s matches the boilerplate\n", archivePath)
+	return nil
+}
+
+var (
+	boilerplateSHA256Re    = regexp.MustCompile(`sha256 = "([0-9a-f]{64})"`)
+	boilerplateIntegrityRe = regexp.MustCompile(`integrity = "(sha256-[A-Za-z0-9+/=]+)"`)
+)
+
+// verifyArchiveSums checks that the archive at archivePath matches the
+// sha256 attribute in boilerplate and, if present, the integrity attribute.
+// If version is not empty, verifyArchiveSums also checks that the boilerplate
+// downloads that release.
+func verifyArchiveSums(archivePath, version string, boilerplate []byte) error {
+	if version != "" && !bytes.Contains(boilerplate, []byte("/releases/download/"+version+"/")) {
+		return fmt.Errorf("boilerplate does not refer to release %s", version)
+	}
+	m := boilerplateSHA256Re.FindSubmatch(boilerplate)
+	if m == nil {
+		return errors.New("boilerplate does not contain a sha256 attribute")
+	}
+	wantSum := string(m[1])
+	digest, err := sha256File(archivePath)
+	if err != nil {
+		return err
+	}
+	if gotSum := sha256Hex(digest); gotSum != wantSum {
+		return fmt.Errorf("%s: sha256 is %s, but boilerplate advertises %s", archivePath, gotSum, wantSum)
+	}
+
+	if m := boilerplateIntegrityRe.FindSubmatch(boilerplate); m != nil {
+		wantIntegrity := string(m[1])
+		if gotIntegrity := integrityString(digest); gotIntegrity != wantIntegrity {
+			return fmt.Errorf("%s: integrity is %s, but boilerplate advertises %s", archivePath, gotIntegrity, wantIntegrity)
+		}
+	}
+	return nil
+}
+
+// downloadArchive downloads the contents of url into a new temporary file.
+// It returns the name of the file and a function that removes it.
+func downloadArchive(ctx context.Context, url string) (name string, cleanup func() error, err error) {
+	f, err := os.CreateTemp("", "rules_go-*.zip")
+	if err != nil {
+		return "", nil, err
+	}
+	name = f.Name()
+	cleanup = func() error { return os.Remove(name) }
+	err = downloadToFile(ctx, url, f)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return name, cleanup, nil
+}
+
+// downloadToFile downloads the contents of url into w.
+func downloadToFile(ctx context.Context, url string, w io.Writer) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("downloading %s: %w", url, err)
+		}
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 The Bazel Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyArchiveSums(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "rules_go.zip")
+	if err := os.WriteFile(archivePath, []byte("rules_go\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	const (
+		goodSum       = "c5689599cc4999776d5f3ac5d5503f5b514d5998285571efcd263666fe41dcdc"
+		badSum        = "0000000000000000000000000000000000000000000000000000000000000000"
+		goodIntegrity = "sha256-xWiVmcxJmXdtXzrF1VA/W1FNWZgoVXHvzSY2Zv5B3Nw="
+		badIntegrity  = "sha256-AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+	)
+
+	for _, tt := range []struct {
+		desc, version, boilerplate string
+		wantErr                    bool
+	}{
+		{
+			desc:        "match",
+			boilerplate: genBoilerplate("v0.50.0", goodSum, "1.22.3", boilerplateOptions{}) + genModuleBoilerplate("v0.50.0", goodIntegrity, boilerplateOptions{}),
+		},
+		{
+			desc:        "match_workspace_only",
+			boilerplate: genBoilerplate("v0.50.0", goodSum, "1.22.3", boilerplateOptions{}),
+		},
+		{
+			desc:        "sha256_mismatch",
+			boilerplate: genBoilerplate("v0.50.0", badSum, "1.22.3", boilerplateOptions{}) + genModuleBoilerplate("v0.50.0", goodIntegrity, boilerplateOptions{}),
+			wantErr:     true,
+		},
+		{
+			desc:        "integrity_mismatch",
+			boilerplate: genBoilerplate("v0.50.0", goodSum, "1.22.3", boilerplateOptions{}) + genModuleBoilerplate("v0.50.0", badIntegrity, boilerplateOptions{}),
+			wantErr:     true,
+		},
+		{
+			desc:        "version_match",
+			version:     "v0.50.0",
+			boilerplate: genBoilerplate("v0.50.0", goodSum, "1.22.3", boilerplateOptions{}),
+		},
+		{
+			desc:        "version_mismatch",
+			version:     "v0.49.0",
+			boilerplate: genBoilerplate("v0.50.0", goodSum, "1.22.3", boilerplateOptions{}),
+			wantErr:     true,
+		},
+		{
+			desc:        "no_sha256",
+			boilerplate: "release notes without boilerplate",
+			wantErr:     true,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := verifyArchiveSums(archivePath, tt.version, []byte(tt.boilerplate))
+			if tt.wantErr && err == nil {
+				t.Error("got nil error, want error")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}